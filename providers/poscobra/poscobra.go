@@ -0,0 +1,134 @@
+// Package poscobra implements a koanf.Provider that reads commandline
+// parameters from a github.com/spf13/cobra Command as conf maps, on top of
+// the posflag provider.
+package poscobra
+
+import (
+	"errors"
+
+	"github.com/knadh/koanf"
+	"github.com/knadh/koanf/providers/posflag"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// annotation is the cobra flag annotation that, when present, overrides the
+// koanf key a flag is mapped to, eg: flags.SetAnnotation("port", annotation,
+// []string{"server.port"}).
+const annotation = "koanf"
+
+// Poscobra implements a cobra command provider.
+type Poscobra struct {
+	delim           string
+	cmd             *cobra.Command
+	ko              *koanf.Koanf
+	valueCallback   func(key string, value string) (string, interface{})
+	keyNameCallback func(flag *pflag.Flag) string
+}
+
+// Option configures some aspect of Poscobra provider.
+type Option func(*Poscobra)
+
+// Provider returns a commandline flags provider that reads the flags of cmd,
+// including those inherited from its parents, and returns a nested
+// map[string]interface{} where the nesting hierarchy of keys is defined by
+// delim. For instance, the delim "." will convert the key
+// `parent.child.key: 1` to `{parent: {child: {key: 1}}}`.
+func Provider(cmd *cobra.Command, delim string, opts ...Option) *Poscobra {
+	p := &Poscobra{
+		cmd:   cmd,
+		delim: delim,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// flagSet merges cmd.Flags() with the flags it inherits from its parents so
+// that persistent flags set up the command tree are picked up regardless of
+// whether the command has been executed yet.
+func (p *Poscobra) flagSet() *pflag.FlagSet {
+	fs := pflag.NewFlagSet(p.cmd.Name(), pflag.ContinueOnError)
+
+	// InheritedFlags has the side effect of merging cmd's own persistent
+	// flags into cmd.Flags() (mergePersistentFlags), which otherwise only
+	// happens once the command tree has been Execute()d. Call it first so
+	// cmd.Flags() below already sees cmd's own persistent flags too.
+	inherited := p.cmd.InheritedFlags()
+
+	p.cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		fs.AddFlag(f)
+	})
+	inherited.VisitAll(func(f *pflag.Flag) {
+		if fs.Lookup(f.Name) == nil {
+			fs.AddFlag(f)
+		}
+	})
+	return fs
+}
+
+// keyName resolves the koanf key a flag should be mapped under, preferring
+// the `koanf` annotation set via f.Annotations / FlagSet.SetAnnotation over
+// the caller supplied RenameCallback, falling back to the flag's own name.
+func (p *Poscobra) keyName(f *pflag.Flag) string {
+	if v, ok := f.Annotations[annotation]; ok && len(v) > 0 && v[0] != "" {
+		return v[0]
+	}
+	if p.keyNameCallback != nil {
+		return p.keyNameCallback(f)
+	}
+	return f.Name
+}
+
+// Read reads the flag variables and returns a nested conf map.
+func (p *Poscobra) Read() (map[string]interface{}, error) {
+	opts := []posflag.Option{posflag.RenameCallback(p.keyName)}
+	if p.ko != nil {
+		opts = append(opts, posflag.ParentKoanf(p.ko))
+	}
+	if p.valueCallback != nil {
+		opts = append(opts, posflag.ValueCallback(p.valueCallback))
+	}
+	return posflag.ProviderWithOptions(p.flagSet(), p.delim, opts...).Read()
+}
+
+// ReadBytes is not supported by the poscobra koanf.
+func (p *Poscobra) ReadBytes() ([]byte, error) {
+	return nil, errors.New("poscobra provider does not support this method")
+}
+
+// Watch is not supported.
+func (p *Poscobra) Watch(cb func(event interface{}, err error)) error {
+	return errors.New("poscobra provider does not support this method")
+}
+
+// ParentKoanf option adds the Koanf instance to see if the
+// the flags defined have been set from other providers, for instance,
+// a config file. If they are not, then the default values of the flags
+// are merged. If they do exist, the flag values are not merged but only
+// the values that have been explicitly set in the command line are merged.
+func ParentKoanf(ko *koanf.Koanf) Option {
+	return func(p *Poscobra) {
+		p.ko = ko
+	}
+}
+
+// ValueCallback options adds the callback
+// takes a (key, value) with the variable name and value and allows you
+// to modify both. This is useful for cases where you may want to return
+// other types like a string slice instead of just a string.
+func ValueCallback(cb func(key string, value string) (string, interface{})) Option {
+	return func(p *Poscobra) {
+		p.valueCallback = cb
+	}
+}
+
+// RenameCallback options adds the possibility to map flags in case when flag name
+// differs from setting name. It is consulted only for flags that do not carry
+// the `koanf` annotation.
+func RenameCallback(cb func(flag *pflag.Flag) string) Option {
+	return func(p *Poscobra) {
+		p.keyNameCallback = cb
+	}
+}
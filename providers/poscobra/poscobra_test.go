@@ -0,0 +1,79 @@
+package poscobra
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func newTestCommand() (root, child *cobra.Command) {
+	root = &cobra.Command{Use: "root"}
+	root.PersistentFlags().String("parent-flag", "root-default", "")
+
+	child = &cobra.Command{Use: "child"}
+	child.PersistentFlags().String("ownpersist", "own-default", "")
+	child.Flags().String("local", "local-default", "")
+
+	root.AddCommand(child)
+	return root, child
+}
+
+// TestReadBeforeExecute verifies that a flag defined directly on a child
+// command's own PersistentFlags is visible to Read even when the provider is
+// built, and the command tree never Execute()d, before that merge would
+// otherwise have happened.
+func TestReadBeforeExecute(t *testing.T) {
+	root, child := newTestCommand()
+
+	if err := root.PersistentFlags().Set("parent-flag", "from-root"); err != nil {
+		t.Fatal(err)
+	}
+	if err := child.PersistentFlags().Set("ownpersist", "own-value"); err != nil {
+		t.Fatal(err)
+	}
+	if err := child.Flags().Set("local", "local-value"); err != nil {
+		t.Fatal(err)
+	}
+
+	mp, err := Provider(child, ".").Read()
+	if err != nil {
+		t.Fatalf("Read returned error: %v", err)
+	}
+
+	if mp["parent-flag"] != "from-root" {
+		t.Errorf("expected inherited parent-flag to be read, got %v", mp["parent-flag"])
+	}
+	if mp["ownpersist"] != "own-value" {
+		t.Errorf("expected child's own persistent flag to be read before Execute(), got %v", mp["ownpersist"])
+	}
+	if mp["local"] != "local-value" {
+		t.Errorf("expected local flag to be read, got %v", mp["local"])
+	}
+}
+
+// TestAnnotationRename verifies that a flag annotated with the `koanf`
+// annotation is mapped to the annotated key instead of its flag name.
+func TestAnnotationRename(t *testing.T) {
+	_, child := newTestCommand()
+
+	if err := child.Flags().Set("local", "local-value"); err != nil {
+		t.Fatal(err)
+	}
+	child.Flags().SetAnnotation("local", annotation, []string{"custom.key"})
+
+	mp, err := Provider(child, ".").Read()
+	if err != nil {
+		t.Fatalf("Read returned error: %v", err)
+	}
+
+	custom, ok := mp["custom"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected nested 'custom' map, got %v", mp)
+	}
+	if custom["key"] != "local-value" {
+		t.Errorf("expected annotated key custom.key to hold the flag's value, got %v", custom["key"])
+	}
+	if _, ok := mp["local"]; ok {
+		t.Errorf("expected annotated flag not to also appear under its flag name, got %v", mp)
+	}
+}
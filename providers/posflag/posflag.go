@@ -4,7 +4,15 @@
 package posflag
 
 import (
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"sort"
+	"strings"
 
 	"github.com/knadh/koanf"
 	"github.com/knadh/koanf/maps"
@@ -18,11 +26,20 @@ type Posflag struct {
 	ko              *koanf.Koanf
 	valueCallback   func(key string, value string) (string, interface{})
 	keyNameCallback func(flag *pflag.Flag) string
+	typeHandlers    map[string]TypeHandlerFunc
+	strictTypes     bool
+	watchSignals    []os.Signal
+	reparseFunc     func(*pflag.FlagSet) error
 }
 
 // Option configures some aspect of Posflag provider.
 type Option func(*Posflag)
 
+// TypeHandlerFunc extracts the typed value of the flag called name out of f.
+// It is used to teach Posflag how to read a pflag.Value.Type() that it does
+// not already know about, or to override the handling of one it does.
+type TypeHandlerFunc func(f *pflag.FlagSet, name string) (interface{}, error)
+
 // Provider returns a commandline flags provider that returns
 // a nested map[string]interface{} of environment variable where the
 // nesting hierarchy of keys are defined by delim. For instance, the
@@ -85,23 +102,78 @@ func (p *Posflag) Read() (map[string]interface{}, error) {
 			}
 		}
 
+		if h, ok := p.typeHandlers[f.Value.Type()]; ok {
+			v, err := h(p.flagset, f.Name)
+			if err != nil {
+				return
+			}
+			mp[keyName] = v
+			return
+		}
+
 		var v interface{}
 		switch f.Value.Type() {
 		case "int":
 			i, _ := p.flagset.GetInt(f.Name)
-			v = int64(i)
+			if p.strictTypes {
+				v = i
+			} else {
+				v = int64(i)
+			}
 		case "int8":
 			i, _ := p.flagset.GetInt8(f.Name)
-			v = int64(i)
+			if p.strictTypes {
+				v = i
+			} else {
+				v = int64(i)
+			}
 		case "int16":
 			i, _ := p.flagset.GetInt16(f.Name)
-			v = int64(i)
+			if p.strictTypes {
+				v = i
+			} else {
+				v = int64(i)
+			}
 		case "int32":
 			i, _ := p.flagset.GetInt32(f.Name)
-			v = int64(i)
+			if p.strictTypes {
+				v = i
+			} else {
+				v = int64(i)
+			}
 		case "int64":
 			i, _ := p.flagset.GetInt64(f.Name)
 			v = int64(i)
+		case "uint":
+			i, _ := p.flagset.GetUint(f.Name)
+			if p.strictTypes {
+				v = i
+			} else {
+				v = uint64(i)
+			}
+		case "uint8":
+			i, _ := p.flagset.GetUint8(f.Name)
+			if p.strictTypes {
+				v = i
+			} else {
+				v = uint64(i)
+			}
+		case "uint16":
+			i, _ := p.flagset.GetUint16(f.Name)
+			if p.strictTypes {
+				v = i
+			} else {
+				v = uint64(i)
+			}
+		case "uint32":
+			i, _ := p.flagset.GetUint32(f.Name)
+			if p.strictTypes {
+				v = i
+			} else {
+				v = uint64(i)
+			}
+		case "uint64":
+			v, _ = p.flagset.GetUint64(f.Name)
 		case "float32":
 			v, _ = p.flagset.GetFloat32(f.Name)
 		case "float":
@@ -112,6 +184,47 @@ func (p *Posflag) Read() (map[string]interface{}, error) {
 			v, _ = p.flagset.GetStringSlice(f.Name)
 		case "intSlice":
 			v, _ = p.flagset.GetIntSlice(f.Name)
+		case "uintSlice":
+			v, _ = p.flagset.GetUintSlice(f.Name)
+		case "int32Slice":
+			v, _ = p.flagset.GetInt32Slice(f.Name)
+		case "int64Slice":
+			v, _ = p.flagset.GetInt64Slice(f.Name)
+		case "float32Slice":
+			v, _ = p.flagset.GetFloat32Slice(f.Name)
+		case "float64Slice":
+			v, _ = p.flagset.GetFloat64Slice(f.Name)
+		case "boolSlice":
+			v, _ = p.flagset.GetBoolSlice(f.Name)
+		case "durationSlice":
+			v, _ = p.flagset.GetDurationSlice(f.Name)
+		case "duration":
+			v, _ = p.flagset.GetDuration(f.Name)
+		case "ip":
+			v, _ = p.flagset.GetIP(f.Name)
+		case "ipNet":
+			v, _ = p.flagset.GetIPNet(f.Name)
+		case "ipMask":
+			v, _ = p.flagset.GetIPv4Mask(f.Name)
+		case "count":
+			i, _ := p.flagset.GetCount(f.Name)
+			if p.strictTypes {
+				v = i
+			} else {
+				v = int64(i)
+			}
+		case "bytesHex":
+			v, _ = p.flagset.GetBytesHex(f.Name)
+		case "bytesBase64":
+			v, _ = p.flagset.GetBytesBase64(f.Name)
+		case "stringArray":
+			v, _ = p.flagset.GetStringArray(f.Name)
+		case "stringToString":
+			v, _ = p.flagset.GetStringToString(f.Name)
+		case "stringToInt":
+			v, _ = p.flagset.GetStringToInt(f.Name)
+		case "stringToInt64":
+			v, _ = p.flagset.GetStringToInt64(f.Name)
 		default:
 			if p.valueCallback != nil {
 				key, value := p.valueCallback(keyName, f.Value.String())
@@ -135,9 +248,146 @@ func (p *Posflag) ReadBytes() ([]byte, error) {
 	return nil, errors.New("pflag provider does not support this method")
 }
 
-// Watch is not supported.
+// WatchEvent is passed to the callback given to Watch whenever a watched
+// signal triggers a re-parse that results in one or more keys changing value.
+type WatchEvent struct {
+	// Changed maps every key whose value changed to its new value.
+	Changed map[string]interface{}
+}
+
+// Watch is not supported unless WithWatchSignals has been used to register
+// one or more os.Signal to listen for. When registered, Watch installs a
+// signal handler that, on receipt of any of those signals, re-parses the
+// FlagSet via the func set with WithReparseFunc (os.Args by default) and
+// invokes cb with a WatchEvent describing which keys changed, letting
+// long-running services live-reload CLI-overridable values without a
+// restart.
 func (p *Posflag) Watch(cb func(event interface{}, err error)) error {
-	return errors.New("posflag provider does not support this method")
+	if len(p.watchSignals) == 0 {
+		return errors.New("posflag provider does not support this method")
+	}
+
+	reparse := p.reparseFunc
+	if reparse == nil {
+		reparse = func(f *pflag.FlagSet) error {
+			return f.Parse(os.Args[1:])
+		}
+	}
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, p.watchSignals...)
+
+	go func() {
+		for range sigCh {
+			before, err := p.Read()
+			if err != nil {
+				cb(nil, err)
+				continue
+			}
+			if err := reparse(p.flagset); err != nil {
+				cb(nil, err)
+				continue
+			}
+			after, err := p.Read()
+			if err != nil {
+				cb(nil, err)
+				continue
+			}
+
+			if changed := diffFlat(before, after, p.delim); len(changed) > 0 {
+				cb(&WatchEvent{Changed: changed}, nil)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// diffFlat flattens before and after with delim and returns the keys whose
+// value changed (or was added/removed), mapped to their new value.
+func diffFlat(before, after map[string]interface{}, delim string) map[string]interface{} {
+	bf, _ := maps.Flatten(before, nil, delim)
+	af, _ := maps.Flatten(after, nil, delim)
+
+	changed := make(map[string]interface{})
+	for k, v := range af {
+		if old, ok := bf[k]; !ok || !reflect.DeepEqual(old, v) {
+			changed[k] = v
+		}
+	}
+	for k := range bf {
+		if _, ok := af[k]; !ok {
+			changed[k] = nil
+		}
+	}
+	return changed
+}
+
+// Apply writes values resolved by ko back into the underlying pflag.FlagSet,
+// so that third-party libraries that only read from pflag (eg: klog, glog,
+// client-go) observe the effective value after config files, env vars etc.
+// have been merged into ko. Precedence is the inverse of ParentKoanf: a flag
+// explicitly set on the command line (f.Changed) is left untouched, a flag
+// whose key exists in ko is set to ko's value, and any other flag is left at
+// its own default.
+func (p *Posflag) Apply(ko *koanf.Koanf) error {
+	var err error
+	p.flagset.VisitAll(func(f *pflag.Flag) {
+		if err != nil || f.Changed {
+			return
+		}
+
+		keyName := f.Name
+		if p.keyNameCallback != nil {
+			keyName = p.keyNameCallback(f)
+		}
+		if !ko.Exists(keyName) {
+			return
+		}
+
+		if serr := f.Value.Set(formatFlagValue(f.Value.Type(), ko.Get(keyName))); serr != nil {
+			err = fmt.Errorf("error applying koanf value to flag %q: %v", f.Name, serr)
+		}
+	})
+	return err
+}
+
+// formatFlagValue renders v the way pflag.Value.Set() expects to parse it for
+// a flag of the given pflag type: bytesHex/bytesBase64 are hex/base64
+// encoded, slices are joined with a comma the same way pflag's own
+// slice/array types do, and maps (eg: a stringToString/stringToInt/
+// stringToInt64 flag's value, which ko.Get may hand back as
+// map[string]string or map[string]interface{}) are rendered as the
+// "k=v,k2=v2" pairs stringToStringValue.Set et al parse.
+func formatFlagValue(typ string, v interface{}) string {
+	if b, ok := v.([]byte); ok {
+		switch typ {
+		case "bytesHex":
+			return hex.EncodeToString(b)
+		case "bytesBase64":
+			return base64.StdEncoding.EncodeToString(b)
+		}
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		parts := make([]string, 0, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			parts = append(parts, fmt.Sprintf("%v", rv.Index(i).Interface()))
+		}
+		return strings.Join(parts, ",")
+	case reflect.Map:
+		keys := rv.MapKeys()
+		parts := make([]string, 0, len(keys))
+		for _, k := range keys {
+			parts = append(parts, fmt.Sprintf("%v=%v", k.Interface(), rv.MapIndex(k).Interface()))
+		}
+		sort.Strings(parts)
+		return strings.Join(parts, ",")
+	default:
+		return fmt.Sprintf("%v", v)
+	}
 }
 
 // ParentKoanf option adds the Koanf instance to see if the
@@ -168,3 +418,47 @@ func RenameCallback(cb func(flag *pflag.Flag) string) Option {
 		p.keyNameCallback = cb
 	}
 }
+
+// WithStrictTypes option makes Read preserve the declared pflag type of a
+// flag's value exactly (int8 stays int8, uint32 stays uint32, and so on)
+// instead of widening integers to int64 and unsigned integers to uint64.
+// This matters for unmarshalers such as mapstructure (with WeaklyTypedInput
+// off) or encoding/json that fail or silently truncate when an int64 is
+// presented for a smaller typed field. The default remains the widening
+// behavior for backward compatibility.
+func WithStrictTypes() Option {
+	return func(p *Posflag) {
+		p.strictTypes = true
+	}
+}
+
+// WithWatchSignals option registers the os.Signal(s) that, on receipt,
+// trigger Watch to re-parse the FlagSet and report changed keys. Without
+// this option, Watch keeps returning an error as before.
+func WithWatchSignals(sig ...os.Signal) Option {
+	return func(p *Posflag) {
+		p.watchSignals = sig
+	}
+}
+
+// WithReparseFunc option overrides how Watch re-parses the FlagSet when a
+// watched signal arrives. The default re-parses os.Args, but callers may
+// want to re-read a flag file or pull from /proc/self/cmdline instead.
+func WithReparseFunc(fn func(*pflag.FlagSet) error) Option {
+	return func(p *Posflag) {
+		p.reparseFunc = fn
+	}
+}
+
+// WithTypeHandler option registers fn as the handler used to read flags whose
+// f.Value.Type() equals typ, overriding (or, for types Read does not already
+// know about, adding to) the built-in type switch. This lets callers plug in
+// support for custom pflag.Value implementations without forking the package.
+func WithTypeHandler(typ string, fn TypeHandlerFunc) Option {
+	return func(p *Posflag) {
+		if p.typeHandlers == nil {
+			p.typeHandlers = make(map[string]TypeHandlerFunc)
+		}
+		p.typeHandlers[typ] = fn
+	}
+}
@@ -0,0 +1,128 @@
+package posflag
+
+import (
+	"testing"
+	"time"
+
+	"github.com/knadh/koanf"
+	"github.com/knadh/koanf/providers/confmap"
+	"github.com/spf13/pflag"
+)
+
+func TestReadNewGetters(t *testing.T) {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.Duration("timeout", 0, "")
+	fs.StringToString("labels", nil, "")
+	if err := fs.Parse([]string{"--timeout=30s", "--labels=a=1,b=2"}); err != nil {
+		t.Fatal(err)
+	}
+
+	mp, err := ProviderWithOptions(fs, ".").Read()
+	if err != nil {
+		t.Fatalf("Read returned error: %v", err)
+	}
+
+	if mp["timeout"] != 30*time.Second {
+		t.Errorf("expected timeout to come back as a time.Duration, got %#v", mp["timeout"])
+	}
+
+	labels, ok := mp["labels"].(map[string]string)
+	if !ok {
+		t.Fatalf("expected labels to come back as map[string]string, got %#v", mp["labels"])
+	}
+	if labels["a"] != "1" || labels["b"] != "2" {
+		t.Errorf("expected labels to be parsed from a=1,b=2, got %v", labels)
+	}
+}
+
+func TestWithStrictTypes(t *testing.T) {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.Int8("retries", 0, "")
+	fs.Uint("workers", 0, "")
+	if err := fs.Parse([]string{"--retries=5", "--workers=3"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Default behavior widens to int64/uint64.
+	mp, err := ProviderWithOptions(fs, ".").Read()
+	if err != nil {
+		t.Fatalf("Read returned error: %v", err)
+	}
+	if _, ok := mp["retries"].(int64); !ok {
+		t.Errorf("expected retries to widen to int64 by default, got %#v", mp["retries"])
+	}
+	if _, ok := mp["workers"].(uint64); !ok {
+		t.Errorf("expected workers to widen to uint64 by default, got %#v", mp["workers"])
+	}
+
+	// WithStrictTypes preserves the flag's declared type.
+	mp, err = ProviderWithOptions(fs, ".", WithStrictTypes()).Read()
+	if err != nil {
+		t.Fatalf("Read returned error: %v", err)
+	}
+	if _, ok := mp["retries"].(int8); !ok {
+		t.Errorf("expected retries to stay int8 with WithStrictTypes, got %#v", mp["retries"])
+	}
+	if _, ok := mp["workers"].(uint); !ok {
+		t.Errorf("expected workers to stay uint with WithStrictTypes, got %#v", mp["workers"])
+	}
+}
+
+func TestApply(t *testing.T) {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.String("host", "localhost", "")
+	fs.Int("port", 8080, "")
+	fs.StringToString("labels", nil, "")
+	if err := fs.Parse([]string{"--host=cli-wins"}); err != nil {
+		t.Fatal(err)
+	}
+
+	ko := koanf.New(".")
+	if err := ko.Load(confmap.Provider(map[string]interface{}{
+		"host":   "from-koanf",
+		"port":   9090,
+		"labels": map[string]interface{}{"a": "1", "b": "2"},
+	}, "."), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	p := ProviderWithOptions(fs, ".", ParentKoanf(ko))
+	if err := p.Apply(ko); err != nil {
+		t.Fatalf("Apply returned error: %v", err)
+	}
+
+	// A flag explicitly set on the command line must not be overwritten.
+	if v, _ := fs.GetString("host"); v != "cli-wins" {
+		t.Errorf("expected CLI-set flag to win, got %q", v)
+	}
+	// An unset flag whose key exists in ko must pick up the koanf value.
+	if v, _ := fs.GetInt("port"); v != 9090 {
+		t.Errorf("expected port to be set from koanf, got %d", v)
+	}
+	// stringToString values must round-trip through the k=v,k2=v2 format.
+	labels, _ := fs.GetStringToString("labels")
+	if labels["a"] != "1" || labels["b"] != "2" {
+		t.Errorf("expected labels to round-trip through Apply, got %v", labels)
+	}
+}
+
+func TestDiffFlat(t *testing.T) {
+	before := map[string]interface{}{"a": map[string]interface{}{"b": 1, "c": "x"}}
+	after := map[string]interface{}{"a": map[string]interface{}{"b": 2, "c": "x"}}
+
+	changed := diffFlat(before, after, ".")
+	if len(changed) != 1 {
+		t.Fatalf("expected exactly one changed key, got %v", changed)
+	}
+	if changed["a.b"] != 2 {
+		t.Errorf("expected a.b to be reported as changed to 2, got %v", changed["a.b"])
+	}
+}
+
+func TestWatchRequiresSignals(t *testing.T) {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	p := ProviderWithOptions(fs, ".")
+	if err := p.Watch(func(event interface{}, err error) {}); err == nil {
+		t.Fatal("expected Watch without WithWatchSignals to return an error")
+	}
+}